@@ -0,0 +1,201 @@
+package liblog
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	defaultBufferSize         = 1024
+	defaultBatchSize          = 64
+	defaultDropNotifyEvery    = 100
+	defaultDropNotifyInterval = 5 * time.Second
+)
+
+// BackpressureMode selects what a Logger does when its ring buffer is
+// full.
+type BackpressureMode int
+
+const (
+	// BlockOnFull makes producers wait for space, matching the original
+	// unbuffered-channel behavior.
+	BlockOnFull BackpressureMode = iota
+	// DropOldest overwrites the oldest buffered message to make room for
+	// the new one.
+	DropOldest
+	// DropNewest discards the incoming message and never blocks the
+	// producer.
+	DropNewest
+)
+
+func parseBackpressureMode(s string) BackpressureMode {
+	switch strings.ToUpper(s) {
+	case "DROPOLDEST":
+		return DropOldest
+	case "DROPNEWEST":
+		return DropNewest
+	default:
+		return BlockOnFull
+	}
+}
+
+// ringBuffer is a bounded circular queue of LogMsg shared between
+// producer goroutines calling push and the single consumer goroutine
+// calling popBatch.
+type ringBuffer struct {
+	mu     sync.Mutex
+	cond   *sync.Cond
+	buf    []LogMsg
+	head   int
+	n      int
+	mode   BackpressureMode
+	closed bool
+}
+
+func newRingBuffer(capacity int, mode BackpressureMode) *ringBuffer {
+	rb := &ringBuffer{buf: make([]LogMsg, capacity), mode: mode}
+	rb.cond = sync.NewCond(&rb.mu)
+	return rb
+}
+
+// push enqueues msg, applying the configured BackpressureMode when the
+// buffer is full. It reports true if a message was lost as a result: msg
+// itself under DropNewest or BlockOnFull-after-close, or the oldest
+// queued message under DropOldest (msg is still queued in that case).
+func (rb *ringBuffer) push(msg LogMsg) (dropped bool) {
+	rb.mu.Lock()
+	defer rb.mu.Unlock()
+	capacity := len(rb.buf)
+	if rb.n == capacity {
+		switch rb.mode {
+		case DropNewest:
+			return true
+		case DropOldest:
+			rb.head = (rb.head + 1) % capacity
+			rb.n--
+			dropped = true
+		case BlockOnFull:
+			for rb.n == capacity && !rb.closed {
+				rb.cond.Wait()
+			}
+			if rb.closed {
+				return true
+			}
+		}
+	}
+	rb.buf[(rb.head+rb.n)%capacity] = msg
+	rb.n++
+	rb.cond.Signal()
+	return dropped
+}
+
+// popBatch blocks until at least one message is available and returns up
+// to max of them, or nil once the buffer has been closed and drained.
+func (rb *ringBuffer) popBatch(max int) []LogMsg {
+	rb.mu.Lock()
+	defer rb.mu.Unlock()
+	for rb.n == 0 && !rb.closed {
+		rb.cond.Wait()
+	}
+	if rb.n == 0 {
+		return nil
+	}
+	k := rb.n
+	if k > max {
+		k = max
+	}
+	out := make([]LogMsg, k)
+	for i := 0; i < k; i++ {
+		out[i] = rb.buf[(rb.head+i)%len(rb.buf)]
+	}
+	rb.head = (rb.head + k) % len(rb.buf)
+	rb.n -= k
+	rb.cond.Broadcast()
+	return out
+}
+
+func (rb *ringBuffer) close() {
+	rb.mu.Lock()
+	rb.closed = true
+	rb.mu.Unlock()
+	rb.cond.Broadcast()
+}
+
+func (rb *ringBuffer) depth() int {
+	rb.mu.Lock()
+	defer rb.mu.Unlock()
+	return rb.n
+}
+
+// BatchSink is implemented by sinks that can write several messages in a
+// single underlying Write call. Sinks that don't implement it receive one
+// WriteMsg call per message instead.
+type BatchSink interface {
+	WriteBatch(msgs []LogMsg) error
+}
+
+// loggerStats backs Logger.Stats() and the dropped-message notifier.
+type loggerStats struct {
+	enqueued int64
+	written  int64
+	dropped  int64
+
+	mu          sync.Mutex
+	sinceNotify int
+	lastNotify  time.Time
+}
+
+func (s *loggerStats) addEnqueued(n int64) { atomic.AddInt64(&s.enqueued, n) }
+func (s *loggerStats) addWritten(n int64)  { atomic.AddInt64(&s.written, n) }
+func (s *loggerStats) addDropped(n int64)  { atomic.AddInt64(&s.dropped, n) }
+
+// Stats reports the logger's ring-buffer counters, suitable for
+// Prometheus-style scraping.
+type Stats struct {
+	Enqueued   int64
+	Written    int64
+	Dropped    int64
+	QueueDepth int
+}
+
+func (logger *Logger) Stats() Stats {
+	return Stats{
+		Enqueued:   atomic.LoadInt64(&logger.stats.enqueued),
+		Written:    atomic.LoadInt64(&logger.stats.written),
+		Dropped:    atomic.LoadInt64(&logger.stats.dropped),
+		QueueDepth: logger.buffer.depth(),
+	}
+}
+
+// onDrop accounts for a dropped message and, once every
+// defaultDropNotifyEvery drops or defaultDropNotifyInterval (whichever
+// comes first), pushes a synthetic warning so operators notice.
+func (logger *Logger) onDrop() {
+	logger.stats.addDropped(1)
+
+	logger.stats.mu.Lock()
+	logger.stats.sinceNotify++
+	due := logger.stats.sinceNotify >= defaultDropNotifyEvery ||
+		time.Since(logger.stats.lastNotify) >= defaultDropNotifyInterval
+	var count int
+	if due {
+		count = logger.stats.sinceNotify
+		logger.stats.sinceNotify = 0
+		logger.stats.lastNotify = time.Now()
+	}
+	logger.stats.mu.Unlock()
+
+	if !due {
+		return
+	}
+	logger.buffer.push(LogMsg{
+		Timestamp: time.Now(),
+		Level:     WarningLevel,
+		Module:    logger.module,
+		ModuleId:  logger.id,
+		Message:   fmt.Sprintf("dropped %d log messages", count),
+	})
+}
@@ -0,0 +1,59 @@
+package liblog
+
+import (
+	"os"
+	"testing"
+)
+
+func TestRingBufferDropOldest(t *testing.T) {
+	rb := newRingBuffer(2, DropOldest)
+	rb.push(LogMsg{Message: "a"})
+	rb.push(LogMsg{Message: "b"})
+	if dropped := rb.push(LogMsg{Message: "c"}); !dropped {
+		t.Fatal("DropOldest should report the eviction of the oldest message as a drop")
+	}
+
+	got := rb.popBatch(2)
+	if len(got) != 2 || got[0].Message != "b" || got[1].Message != "c" {
+		t.Fatalf("got %+v, want [b c] (a should have been evicted)", got)
+	}
+}
+
+func TestRingBufferDropNewest(t *testing.T) {
+	rb := newRingBuffer(1, DropNewest)
+	rb.push(LogMsg{Message: "a"})
+	if dropped := rb.push(LogMsg{Message: "b"}); !dropped {
+		t.Fatal("DropNewest should report the incoming message as dropped")
+	}
+
+	got := rb.popBatch(2)
+	if len(got) != 1 || got[0].Message != "a" {
+		t.Fatalf("got %+v, want [a]", got)
+	}
+}
+
+func TestRingBufferPopBatchNilAfterClose(t *testing.T) {
+	rb := newRingBuffer(4, BlockOnFull)
+	rb.close()
+	if got := rb.popBatch(4); got != nil {
+		t.Fatalf("popBatch on a closed, empty buffer = %+v, want nil", got)
+	}
+}
+
+func TestLoggerStatsCountsDropOldestEvictions(t *testing.T) {
+	os.Setenv("LOG_BUFFER_SIZE", "1")
+	os.Setenv("LOG_BACKPRESSURE", "DROPOLDEST")
+	defer os.Unsetenv("LOG_BUFFER_SIZE")
+	defer os.Unsetenv("LOG_BACKPRESSURE")
+
+	logger := Init("drop-oldest-stats")
+	defer logger.StopSync()
+
+	logger.enqueue(LogMsg{Message: "a"})
+	logger.enqueue(LogMsg{Message: "b"})
+	logger.enqueue(LogMsg{Message: "c"})
+
+	if got := logger.Stats().Dropped; got == 0 {
+		t.Fatal("Stats().Dropped should count messages evicted under DropOldest, not just DropNewest")
+	}
+}
@@ -0,0 +1,83 @@
+package liblog
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"unicode/utf8"
+)
+
+// expandMessage applies the logger's level filter and splits an
+// oversized message into msgLen-bounded parts, returning nil if the
+// message is filtered out. Each returned part carries Chunk/Chunks so
+// consumers can reassemble the original text in order. A message is
+// never split mid-rune: splitPoint prefers the last newline, space, or
+// tab within the window and otherwise backs up to a rune boundary.
+//
+// If the message still exceeds maxChunks parts, the final chunk is
+// truncated and marked with TruncatedSHA256, the sha256 of the dropped
+// tail, rather than emitting an unbounded number of parts.
+func (logger *Logger) expandMessage(msg LogMsg) []LogMsg {
+	if msg.Level < logger.effectiveLevel(msg.Module, msg.SrcFile) {
+		return nil
+	}
+	if len(msg.Message) <= logger.msgLen {
+		return []LogMsg{msg}
+	}
+
+	text := msg.Message
+	var parts []LogMsg
+	for len(text) > logger.msgLen {
+		if logger.maxChunks > 0 && len(parts) == logger.maxChunks-1 {
+			cut := splitPoint(text, logger.msgLen)
+			sum := sha256.Sum256([]byte(text[cut:]))
+			final := msg
+			final.Message = text[:cut]
+			final.Truncated = true
+			final.TruncatedSHA256 = hex.EncodeToString(sum[:])
+			parts = append(parts, final)
+			text = ""
+			break
+		}
+		cut := splitPoint(text, logger.msgLen)
+		part := msg
+		part.Message = text[:cut]
+		parts = append(parts, part)
+		text = text[cut:]
+	}
+	if text != "" {
+		final := msg
+		final.Message = text
+		parts = append(parts, final)
+	}
+
+	total := len(parts)
+	for i := range parts {
+		parts[i].Chunk = i + 1
+		parts[i].Chunks = total
+	}
+	return parts
+}
+
+// splitPoint picks where to cut text at or before limit bytes: the last
+// newline, space, or tab within the window if there is one, otherwise the
+// nearest rune boundary at or before limit. If limit falls inside the
+// first rune, the cut is made after that whole rune, so the part can be
+// longer than limit.
+func splitPoint(text string, limit int) int {
+	if limit >= len(text) {
+		return len(text)
+	}
+	if i := strings.LastIndexAny(text[:limit], "\n \t"); i > 0 {
+		return i + 1
+	}
+	cut := limit
+	for cut > 0 && !utf8.RuneStart(text[cut]) {
+		cut--
+	}
+	if cut == 0 {
+		_, size := utf8.DecodeRuneInString(text)
+		return size
+	}
+	return cut
+}
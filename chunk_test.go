@@ -0,0 +1,78 @@
+package liblog
+
+import (
+	"testing"
+	"unicode/utf8"
+)
+
+func TestExpandMessageSplitsOnWordBoundary(t *testing.T) {
+	logger := Init("chunk")
+	defer logger.StopSync()
+	logger.msgLen = 10
+
+	parts := logger.expandMessage(LogMsg{Level: InfoLevel, Message: "hello world foo"})
+	if len(parts) < 2 {
+		t.Fatalf("got %d parts, want a split message", len(parts))
+	}
+	if parts[0].Message != "hello " {
+		t.Fatalf("first part = %q, want %q (cut after the last space within msgLen)", parts[0].Message, "hello ")
+	}
+	for i, p := range parts {
+		if p.Chunk != i+1 || p.Chunks != len(parts) {
+			t.Fatalf("part %d has Chunk=%d Chunks=%d, want %d/%d", i, p.Chunk, p.Chunks, i+1, len(parts))
+		}
+	}
+
+	var rebuilt string
+	for _, p := range parts {
+		rebuilt += p.Message
+	}
+	if rebuilt != "hello world foo" {
+		t.Fatalf("rebuilt = %q, want original message back", rebuilt)
+	}
+}
+
+func TestExpandMessageFiltersBelowLevel(t *testing.T) {
+	logger := Init("chunk-filter")
+	defer logger.StopSync()
+	logger.Level = WarningLevel
+
+	if got := logger.expandMessage(LogMsg{Level: InfoLevel, Message: "skip me"}); got != nil {
+		t.Fatalf("expandMessage = %v, want nil for a message below the logger's level", got)
+	}
+}
+
+func TestExpandMessageTruncatesAtMaxChunks(t *testing.T) {
+	logger := Init("chunk-truncate")
+	defer logger.StopSync()
+	logger.msgLen = 4
+	logger.maxChunks = 2
+
+	parts := logger.expandMessage(LogMsg{Level: InfoLevel, Message: "aaaa bbbb cccc dddd"})
+	last := parts[len(parts)-1]
+	if !last.Truncated || last.TruncatedSHA256 == "" {
+		t.Fatalf("last part = %+v, want Truncated with a TruncatedSHA256", last)
+	}
+	if len(parts) != logger.maxChunks {
+		t.Fatalf("got %d parts, want exactly maxChunks=%d", len(parts), logger.maxChunks)
+	}
+}
+
+func TestSplitPointKeepsMultiByteRunesIntact(t *testing.T) {
+	text := "日本語abc"
+	cut := splitPoint(text, 4)
+	if !isValidUTF8Prefix(text, cut) {
+		t.Fatalf("splitPoint(%q, 4) = %d, which cuts a rune in half", text, cut)
+	}
+}
+
+func isValidUTF8Prefix(s string, n int) bool {
+	for i := 0; i < n; {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError && size == 1 {
+			return false
+		}
+		i += size
+	}
+	return true
+}
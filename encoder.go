@@ -0,0 +1,110 @@
+package liblog
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Encoder renders a LogMsg to bytes for a sink that writes text, such as
+// consoleSink or writerSink. File/conn/smtp sinks encode their own JSON
+// regardless of Encoder, since downstream tooling consuming those
+// expects a stable wire format.
+type Encoder interface {
+	Encode(m LogMsg) []byte
+}
+
+func encoderFromEnv() Encoder {
+	switch strings.ToUpper(os.Getenv("LOG_FORMAT")) {
+	case "LOGFMT":
+		return logfmtEncoder{}
+	case "CONSOLE":
+		return newConsoleEncoder()
+	default:
+		return jsonEncoder{}
+	}
+}
+
+// jsonEncoder is the historical default: one JSON object per line.
+type jsonEncoder struct{}
+
+func (jsonEncoder) Encode(m LogMsg) []byte {
+	bytestring, err := json.Marshal(m)
+	if err != nil {
+		return nil
+	}
+	return append(bytestring, '\n')
+}
+
+// logfmtEncoder renders `key=value` pairs, e.g.
+// `timestamp=... level=INFO service=foo msg="..."`.
+type logfmtEncoder struct{}
+
+func (logfmtEncoder) Encode(m LogMsg) []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "timestamp=%s level=%s service=%s", m.Timestamp.Format(time.RFC3339), levelName(m.Level), logfmtQuote(m.Module))
+	if m.ModuleId != "" {
+		fmt.Fprintf(&b, " service_id=%s", logfmtQuote(m.ModuleId))
+	}
+	fmt.Fprintf(&b, " msg=%s", logfmtQuote(m.Message))
+	for k, v := range m.Fields {
+		fmt.Fprintf(&b, " %s=%s", k, logfmtQuote(fmt.Sprint(v)))
+	}
+	b.WriteByte('\n')
+	return []byte(b.String())
+}
+
+func logfmtQuote(s string) string {
+	if strings.ContainsAny(s, " \t\"=") {
+		return strconv.Quote(s)
+	}
+	return s
+}
+
+// consoleEncoder is a human-readable formatter with ANSI color per
+// level. Color is enabled when stdout is a character device: a terminal,
+// but also a device such as /dev/null; a file or pipe gets no color.
+type consoleEncoder struct {
+	color bool
+}
+
+func newConsoleEncoder() *consoleEncoder {
+	return &consoleEncoder{color: isTerminal(os.Stdout)}
+}
+
+var consoleLevelColors = map[LogLevel]string{
+	DebugLevel:   "\x1b[36m",
+	InfoLevel:    "\x1b[32m",
+	WarningLevel: "\x1b[33m",
+	ErrorLevel:   "\x1b[31m",
+}
+
+const consoleColorReset = "\x1b[0m"
+
+func (e *consoleEncoder) Encode(m LogMsg) []byte {
+	var b strings.Builder
+	if e.color {
+		b.WriteString(consoleLevelColors[m.Level])
+	}
+	fmt.Fprintf(&b, "%s [%-7s]", m.Timestamp.Format("2006-01-02T15:04:05.000Z07:00"), levelName(m.Level))
+	if e.color {
+		b.WriteString(consoleColorReset)
+	}
+	fmt.Fprintf(&b, " %s: %s", m.Module, m.Message)
+	for k, v := range m.Fields {
+		fmt.Fprintf(&b, " %s=%v", k, v)
+	}
+	b.WriteByte('\n')
+	return []byte(b.String())
+}
+
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
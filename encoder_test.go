@@ -0,0 +1,41 @@
+package liblog
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestJSONEncoderIncludesFields(t *testing.T) {
+	e := jsonEncoder{}
+	out := e.Encode(LogMsg{Message: "hi", Level: InfoLevel, Fields: map[string]interface{}{"k": "v"}})
+
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(out, &decoded); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if decoded["k"] != "v" {
+		t.Fatalf("fields = %v, want k=v merged in", decoded)
+	}
+}
+
+func TestLogfmtEncoderQuotesValuesWithSpaces(t *testing.T) {
+	e := logfmtEncoder{}
+	out := string(e.Encode(LogMsg{Message: "hello world", Level: WarningLevel, Module: "svc"}))
+
+	if !strings.Contains(out, `msg="hello world"`) {
+		t.Fatalf("got %q, want a quoted msg field", out)
+	}
+	if !strings.Contains(out, "level=WARNING") {
+		t.Fatalf("got %q, want level=WARNING", out)
+	}
+}
+
+func TestLogfmtQuoteLeavesPlainValuesAlone(t *testing.T) {
+	if got := logfmtQuote("plain"); got != "plain" {
+		t.Fatalf("logfmtQuote(%q) = %q, want unchanged", "plain", got)
+	}
+	if got := logfmtQuote("has space"); got == "has space" {
+		t.Fatal("logfmtQuote should quote values containing spaces")
+	}
+}
@@ -0,0 +1,93 @@
+package liblog
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"time"
+)
+
+// FieldsFromContext extracts structured fields (e.g. trace/span IDs) from
+// a context.Context passed to one of the *Ctx logging methods. It is nil
+// by default; assign it once at startup to plug in a tracing library.
+var FieldsFromContext func(ctx context.Context) map[string]interface{}
+
+// With returns a child Logger that prepends kv, an alternating sequence
+// of keys and values as in slog, to the Fields of every message it logs.
+// The child shares the parent's sinks and output pipeline.
+func (logger *Logger) With(kv ...interface{}) *Logger {
+	child := new(Logger)
+	*child = *logger
+	child.fields = mergeFields(logger.fields, pairsToFields(kv))
+	return child
+}
+
+func pairsToFields(kv []interface{}) map[string]interface{} {
+	if len(kv) == 0 {
+		return nil
+	}
+	fields := make(map[string]interface{}, len(kv)/2+1)
+	for i := 0; i < len(kv); i += 2 {
+		key, _ := kv[i].(string)
+		if key == "" {
+			key = fmt.Sprint(kv[i])
+		}
+		if i+1 < len(kv) {
+			fields[key] = kv[i+1]
+		} else {
+			fields[key] = nil
+		}
+	}
+	return fields
+}
+
+func mergeFields(base, extra map[string]interface{}) map[string]interface{} {
+	if len(base) == 0 {
+		return extra
+	}
+	if len(extra) == 0 {
+		return base
+	}
+	merged := make(map[string]interface{}, len(base)+len(extra))
+	for k, v := range base {
+		merged[k] = v
+	}
+	for k, v := range extra {
+		merged[k] = v
+	}
+	return merged
+}
+
+func (logger *Logger) logCtx(ctx context.Context, level LogLevel, format string, values ...interface{}) {
+	_, fileName, lineNumber, _ := runtime.Caller(2)
+	var ctxFields map[string]interface{}
+	if FieldsFromContext != nil {
+		ctxFields = FieldsFromContext(ctx)
+	}
+	logger.enqueue(LogMsg{
+		Timestamp: time.Now(),
+		Level:     level,
+		Module:    logger.module,
+		ModuleId:  logger.id,
+		Message:   fmt.Sprintf(format, values...),
+		SrcFile:   srcFileForLog(fileName),
+		SrcLine:   lineNumber,
+		Fields:    mergeFields(logger.fields, ctxFields),
+	})
+}
+
+func (logger *Logger) DebugCtx(ctx context.Context, format string, values ...interface{}) {
+	logger.logCtx(ctx, DebugLevel, format, values...)
+}
+
+func (logger *Logger) InfoCtx(ctx context.Context, format string, values ...interface{}) {
+	logger.logCtx(ctx, InfoLevel, format, values...)
+}
+
+func (logger *Logger) WarningCtx(ctx context.Context, format string, values ...interface{}) {
+	logger.logCtx(ctx, WarningLevel, format, values...)
+}
+
+func (logger *Logger) ErrorCtx(ctx context.Context, format string, values ...interface{}) {
+	logger.logCtx(ctx, ErrorLevel, format, values...)
+}
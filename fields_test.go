@@ -0,0 +1,38 @@
+package liblog
+
+import "testing"
+
+func TestWithMergesFieldsAndSharesSinks(t *testing.T) {
+	logger := Init("fields")
+	defer logger.StopSync()
+
+	child := logger.With("a", 1)
+	grandchild := child.With("b", 2)
+
+	if grandchild.fields["a"] != 1 || grandchild.fields["b"] != 2 {
+		t.Fatalf("fields = %v, want a=1 b=2 merged from both With calls", grandchild.fields)
+	}
+	if grandchild.sinkSet != logger.sinkSet {
+		t.Fatal("With's child should share the parent's sinkSet, not its own copy")
+	}
+}
+
+func TestPairsToFieldsOddLengthDefaultsToNil(t *testing.T) {
+	fields := pairsToFields([]interface{}{"key"})
+	v, ok := fields["key"]
+	if !ok || v != nil {
+		t.Fatalf("fields = %v, want key=nil for a dangling key with no value", fields)
+	}
+}
+
+func TestMergeFieldsDoesNotMutateBase(t *testing.T) {
+	base := map[string]interface{}{"a": 1}
+	merged := mergeFields(base, map[string]interface{}{"b": 2})
+
+	if len(base) != 1 {
+		t.Fatalf("base = %v, want unchanged by mergeFields", base)
+	}
+	if merged["a"] != 1 || merged["b"] != 2 {
+		t.Fatalf("merged = %v, want a=1 b=2", merged)
+	}
+}
@@ -6,10 +6,8 @@ import (
 	"io"
 	"log"
 	"os"
-	"path/filepath"
 	"runtime"
 	"strconv"
-	"strings"
 	"time"
 )
 
@@ -37,77 +35,107 @@ func (l LogLevel) MarshalJSON() ([]byte, error) {
 }
 
 type LogMsg struct {
-	Timestamp time.Time `json:"timestamp"`
-	Level     LogLevel  `json:"level"`
-	Message   string    `json:"message"`
-	Module    string    `json:"service"`
-	ModuleId  string    `json:"service_id,omitempty"`
-	SrcFile   string    `json:"src_file,omitempty"`
-	SrcLine   int       `json:"src_line,omitempty"`
+	Timestamp time.Time              `json:"timestamp"`
+	Level     LogLevel               `json:"level"`
+	Message   string                 `json:"message"`
+	Module    string                 `json:"service"`
+	ModuleId  string                 `json:"service_id,omitempty"`
+	SrcFile   string                 `json:"src_file,omitempty"`
+	SrcLine   int                    `json:"src_line,omitempty"`
+	Fields    map[string]interface{} `json:"-"`
+
+	// Chunk/Chunks are set on every part of a message split by
+	// expandMessage so consumers can reassemble the original text in
+	// order; both are zero for messages that fit in one piece.
+	Chunk  int `json:"chunk,omitempty"`
+	Chunks int `json:"chunks,omitempty"`
+
+	// Truncated/TruncatedSHA256 are set on the final chunk when a
+	// message hit LOG_MAX_CHUNKS before it was fully emitted;
+	// TruncatedSHA256 is the sha256 of the dropped tail.
+	Truncated       bool   `json:"truncated,omitempty"`
+	TruncatedSHA256 string `json:"truncated_sha256,omitempty"`
+}
+
+// MarshalJSON encodes the fixed LogMsg fields as usual and splices Fields
+// in as additional top-level keys, so a caller that attached structured
+// fields via Logger.With gets them alongside timestamp/level/message
+// rather than nested under a "fields" key.
+func (m LogMsg) MarshalJSON() ([]byte, error) {
+	type plain LogMsg
+	base, err := json.Marshal(plain(m))
+	if err != nil {
+		return nil, err
+	}
+	if len(m.Fields) == 0 {
+		return base, nil
+	}
+	var merged map[string]interface{}
+	if err := json.Unmarshal(base, &merged); err != nil {
+		return nil, err
+	}
+	for k, v := range m.Fields {
+		if _, exists := merged[k]; !exists {
+			merged[k] = v
+		}
+	}
+	return json.Marshal(merged)
 }
 
 type Logger struct {
-	module  string
-	id      string
-	output  chan LogMsg
-	Level   LogLevel
-	writers []io.Writer
-	stop    chan bool
-	msgLen  int
+	module    string
+	id        string
+	buffer    *ringBuffer
+	Level     LogLevel
+	writers   []io.Writer
+	sinkSet   *sinkSet
+	stop      chan bool
+	msgLen    int
+	maxChunks int
+	batchSize int
+	fields    map[string]interface{}
+	vmodule   *vmoduleState
+	stats     *loggerStats
 }
 
 var singleLogger *Logger
 
-func (logger *Logger) printMessage(msg LogMsg) {
-	if msg.Level < logger.Level {
-		return
-	}
-	for len(msg.Message) > logger.msgLen {
-		text := msg.Message
-		index := -1
-		i := strings.Index(text, "\n")
-		for i != -1 && i <= logger.msgLen {
-			index = i
-			i = strings.Index(text[i+1:], "\n")
-			if i == -1 {
-				break
-			}
-			i = i + index + 1
+// writeToSinks hands a batch of already level-filtered, chunk-split
+// messages to every sink. Sinks implementing BatchSink get the whole
+// batch in one call; others fall back to one WriteMsg per message.
+func (logger *Logger) writeToSinks(batch []LogMsg) {
+	for _, s := range logger.sinkSet.snapshot() {
+		if bs, ok := s.(BatchSink); ok {
+			bs.WriteBatch(batch)
+			continue
 		}
-		var msgPart = msg
-		if index == -1 {
-			msgPart.Message = text[:logger.msgLen]
-			text = text[logger.msgLen:] // warning: may split UTF8 symbol apart
-		} else {
-			msgPart.Message = text[:index]
-			text = text[index+1:]
+		for _, m := range batch {
+			s.WriteMsg(m)
 		}
-		bytestring, _ := json.Marshal(msgPart)
-		fmt.Printf("%s\n", string(bytestring))
-		for _, w := range logger.writers {
-			fmt.Fprintf(w, "%s\n", string(bytestring))
-		}
-		msgPart.Message = text
 	}
-	bytestring, _ := json.Marshal(msg)
-	bytestring = append(bytestring, byte('\n'))
-	os.Stdout.Write(bytestring)
-	for _, w := range logger.writers {
-		w.Write(bytestring)
+}
+
+// enqueue pushes msg onto the ring buffer, honoring the logger's
+// configured backpressure mode, and accounts for it in Stats.
+func (logger *Logger) enqueue(msg LogMsg) {
+	logger.stats.addEnqueued(1)
+	if logger.buffer.push(msg) {
+		logger.onDrop()
 	}
 }
 
 func (logger *Logger) log(level LogLevel, format string, values ...interface{}) {
 	_, fileName, lineNumber, _ := runtime.Caller(2)
-	logger.output <- LogMsg{
+	logger.enqueue(LogMsg{
 		Timestamp: time.Now(),
 		Level:     level,
 		Module:    logger.module,
 		ModuleId:  logger.id,
 		Message:   fmt.Sprintf(format, values...),
-		SrcFile:   filepath.Base(fileName),
+		SrcFile:   srcFileForLog(fileName),
 		SrcLine:   lineNumber,
-	}
+		Fields:    logger.fields,
+	})
 }
 
 // OBJECT
@@ -115,9 +143,11 @@ func (logger *Logger) log(level LogLevel, format string, values ...interface{})
 func Init(module string) *Logger {
 	var logger = new(Logger)
 	logger.module = module
-	logger.output = make(chan LogMsg)
 	logger.writers = make([]io.Writer, 0)
+	logger.sinkSet = newSinkSet(new(consoleSink))
 	logger.stop = make(chan bool)
+	logger.vmodule = new(vmoduleState)
+	logger.stats = new(loggerStats)
 	level := os.Getenv("LOGLEVEL")
 	switch level {
 	case "ERROR", "3":
@@ -133,9 +163,27 @@ func Init(module string) *Logger {
 	if logger.msgLen == 0 {
 		logger.msgLen = MaxMsgLength
 	}
+	logger.maxChunks, _ = strconv.Atoi(os.Getenv("LOG_MAX_CHUNKS"))
+	bufSize, _ := strconv.Atoi(os.Getenv("LOG_BUFFER_SIZE"))
+	if bufSize <= 0 {
+		bufSize = defaultBufferSize
+	}
+	logger.buffer = newRingBuffer(bufSize, parseBackpressureMode(os.Getenv("LOG_BACKPRESSURE")))
+	logger.batchSize = defaultBatchSize
 	go func() {
-		for msg := range logger.output {
-			logger.printMessage(msg)
+		for {
+			batch := logger.buffer.popBatch(logger.batchSize)
+			if batch == nil {
+				break
+			}
+			var expanded []LogMsg
+			for _, msg := range batch {
+				expanded = append(expanded, logger.expandMessage(msg)...)
+			}
+			if len(expanded) > 0 {
+				logger.writeToSinks(expanded)
+			}
+			logger.stats.addWritten(int64(len(batch)))
 			runtime.Gosched()
 		}
 		logger.stop <- true
@@ -160,13 +208,17 @@ func (logger *Logger) Error(format string, values ...interface{}) {
 }
 
 func (logger *Logger) Stop() {
-	close(logger.output)
+	logger.buffer.close()
 }
 
 func (logger *Logger) StopSync() {
-	close(logger.output)
+	logger.buffer.close()
 	<-logger.stop
 	close(logger.stop)
+	for _, s := range logger.sinkSet.snapshot() {
+		s.Flush()
+		s.Destroy()
+	}
 }
 
 type LogWriter struct {
@@ -205,14 +257,81 @@ func (logger *Logger) ErrorLogger(prefix string, flags int) *log.Logger {
 	return log.New(logger.ErrorWriter(), prefix, flags)
 }
 
+// AddWriter is a thin wrapper around the sink subsystem that feeds the
+// given io.Writer its own encoded copy of every message, JSON unless
+// LOG_FORMAT or SetEncoder selects another Encoder. Prefer
+// Configure for anything beyond a plain writer.
 func (logger *Logger) AddWriter(writer io.Writer) {
 	logger.writers = append(logger.writers, writer)
+	logger.sinkSet.append(&writerSink{w: writer})
+}
+
+// Configure replaces the logger's sinks with the set described by
+// jsonConfig, a JSON object keyed by registered sink name, e.g.
+// `{"console":{},"file":{"filename":"app.log","rotate":"daily"}}`.
+// Sinks already attached via AddWriter are left untouched. The sinks
+// being replaced are flushed and destroyed so their file handles,
+// connections, and goroutines (e.g. the smtp sink's batching ticker)
+// don't leak.
+func (logger *Logger) Configure(jsonConfig string) error {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(jsonConfig), &raw); err != nil {
+		return err
+	}
+	sinks := make([]LogSink, 0, len(raw))
+	for name, cfg := range raw {
+		factory, ok := sinkFactories[name]
+		if !ok {
+			return fmt.Errorf("liblog: unknown sink %q", name)
+		}
+		sink := factory()
+		if err := sink.Init(string(cfg)); err != nil {
+			return fmt.Errorf("liblog: init sink %q: %w", name, err)
+		}
+		sinks = append(sinks, sink)
+	}
+	for _, w := range logger.writers {
+		sinks = append(sinks, &writerSink{w: w})
+	}
+
+	old := logger.sinkSet.replace(sinks)
+	for _, s := range old {
+		s.Flush()
+		s.Destroy()
+	}
+	return nil
 }
 
 func (logger *Logger) SetModuleId(id string) {
 	logger.id = id
 }
 
+// SetLevel changes the logger's base verbosity level, used whenever
+// SetVModule hasn't set a more specific level for a given module/file.
+// It also invalidates the vmodule cache, since cached lookups fall back
+// to the base Level and would otherwise keep returning the old value.
+func (logger *Logger) SetLevel(level LogLevel) {
+	logger.Level = level
+	logger.vmodule.invalidateCache()
+}
+
+// SetEncoder overrides how the logger's console output and any writers
+// added via AddWriter render each LogMsg. File/conn/smtp sinks keep
+// their own JSON encoding regardless, since downstream tooling consuming
+// those expects a stable wire format. It applies to the sinks attached
+// when it is called; sinks added afterwards by AddWriter or Configure
+// use the encoder selected by LOG_FORMAT.
+func (logger *Logger) SetEncoder(e Encoder) {
+	for _, s := range logger.sinkSet.snapshot() {
+		switch sink := s.(type) {
+		case *consoleSink:
+			sink.setEncoder(e)
+		case *writerSink:
+			sink.setEncoder(e)
+		}
+	}
+}
+
 // SINGLETON
 
 func Singleton() *Logger {
@@ -0,0 +1,159 @@
+package liblog
+
+import (
+	"io"
+	"os"
+	"sync"
+)
+
+// LogSink is a pluggable log backend. Init receives the sink's slice of
+// the JSON object passed to Logger.Configure; WriteMsg is called once
+// per emitted LogMsg (unless the sink also implements BatchSink, which
+// receives WriteBatch instead) so each sink can pick its own encoding
+// instead of receiving a pre-serialized line.
+type LogSink interface {
+	Init(jsonConfig string) error
+	WriteMsg(m LogMsg) error
+	Flush()
+	Destroy()
+}
+
+// sinkSet holds a Logger's sinks behind a mutex so AddWriter/Configure/
+// SetEncoder (writers) and the consumer goroutine (reader, via
+// Logger.writeToSinks) can run concurrently. It is shared by value via
+// pointer between a Logger and any children created by With, so the
+// sync.RWMutex itself is never copied.
+type sinkSet struct {
+	mu    sync.RWMutex
+	sinks []LogSink
+}
+
+func newSinkSet(sinks ...LogSink) *sinkSet {
+	return &sinkSet{sinks: sinks}
+}
+
+// snapshot returns the current sink slice. The slice is only ever
+// replaced wholesale (never mutated in place), so the caller can safely
+// range over the returned value without holding any lock.
+func (ss *sinkSet) snapshot() []LogSink {
+	ss.mu.RLock()
+	defer ss.mu.RUnlock()
+	return ss.sinks
+}
+
+func (ss *sinkSet) append(s LogSink) {
+	ss.mu.Lock()
+	ss.sinks = append(append([]LogSink{}, ss.sinks...), s)
+	ss.mu.Unlock()
+}
+
+// replace installs sinks as the current set and returns the sinks that
+// were replaced, so the caller can Flush/Destroy them.
+func (ss *sinkSet) replace(sinks []LogSink) []LogSink {
+	ss.mu.Lock()
+	old := ss.sinks
+	ss.sinks = sinks
+	ss.mu.Unlock()
+	return old
+}
+
+var sinkFactories = map[string]func() LogSink{}
+
+// RegisterSink makes a sink factory available under name for use by
+// Logger.Configure. Built-in sinks ("console", "file", "conn", "smtp")
+// register themselves in init().
+func RegisterSink(name string, factory func() LogSink) {
+	sinkFactories[name] = factory
+}
+
+func init() {
+	RegisterSink("console", func() LogSink { return new(consoleSink) })
+	RegisterSink("file", func() LogSink { return new(fileSink) })
+	RegisterSink("conn", func() LogSink { return new(connSink) })
+	RegisterSink("smtp", func() LogSink { return new(smtpSink) })
+}
+
+// consoleSink is the default sink, writing each message to stdout
+// through a swappable Encoder (Logger.SetEncoder), JSON by default.
+// encoder is guarded by mu since SetEncoder can run concurrently with
+// the consumer goroutine's WriteMsg calls.
+type consoleSink struct {
+	mu      sync.RWMutex
+	encoder Encoder
+}
+
+func (s *consoleSink) Init(jsonConfig string) error {
+	s.setEncoder(encoderFromEnv())
+	return nil
+}
+
+func (s *consoleSink) setEncoder(e Encoder) {
+	s.mu.Lock()
+	s.encoder = e
+	s.mu.Unlock()
+}
+
+func (s *consoleSink) getEncoder() Encoder {
+	s.mu.RLock()
+	e := s.encoder
+	s.mu.RUnlock()
+	if e == nil {
+		e = encoderFromEnv()
+		s.setEncoder(e)
+	}
+	return e
+}
+
+func (s *consoleSink) WriteMsg(m LogMsg) error {
+	_, err := os.Stdout.Write(s.getEncoder().Encode(m))
+	return err
+}
+
+func (s *consoleSink) Flush()   {}
+func (s *consoleSink) Destroy() {}
+
+// writerSink adapts a plain io.Writer, as accepted by AddWriter, to the
+// LogSink interface, rendering through the same swappable Encoder as
+// consoleSink. It never closes w: AddWriter is documented as a thin
+// wrapper, and callers passing os.Stderr or a shared net.Conn never
+// opted into it being closed on StopSync.
+type writerSink struct {
+	w       io.Writer
+	mu      sync.RWMutex
+	encoder Encoder
+}
+
+func (s *writerSink) Init(jsonConfig string) error {
+	s.setEncoder(encoderFromEnv())
+	return nil
+}
+
+func (s *writerSink) setEncoder(e Encoder) {
+	s.mu.Lock()
+	s.encoder = e
+	s.mu.Unlock()
+}
+
+func (s *writerSink) getEncoder() Encoder {
+	s.mu.RLock()
+	e := s.encoder
+	s.mu.RUnlock()
+	if e == nil {
+		e = encoderFromEnv()
+		s.setEncoder(e)
+	}
+	return e
+}
+
+func (s *writerSink) WriteMsg(m LogMsg) error {
+	_, err := s.w.Write(s.getEncoder().Encode(m))
+	return err
+}
+
+func (s *writerSink) Flush() {
+	if f, ok := s.w.(interface{ Flush() }); ok {
+		f.Flush()
+	}
+}
+
+func (s *writerSink) Destroy() {}
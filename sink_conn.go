@@ -0,0 +1,130 @@
+package liblog
+
+import (
+	"encoding/json"
+	"net"
+	"sync"
+)
+
+// connSinkConfig is the `conn` key of a Logger.Configure JSON document.
+// Net/Addr are passed straight to net.Dial (e.g. "tcp", "host:514").
+// Reconnect redials once on a failed write; ReconnectOnMsg dials a fresh
+// connection for every WriteMsg call instead of keeping one alive, while
+// WriteBatch dials one connection per batch, shared by all its messages.
+type connSinkConfig struct {
+	Net            string `json:"net"`
+	Addr           string `json:"addr"`
+	Reconnect      bool   `json:"reconnect"`
+	ReconnectOnMsg bool   `json:"reconnectOnMsg"`
+}
+
+// connSink writes JSON lines to a keep-alive TCP or UDP connection.
+type connSink struct {
+	cfg  connSinkConfig
+	mu   sync.Mutex
+	conn net.Conn
+}
+
+func (s *connSink) Init(jsonConfig string) error {
+	if err := json.Unmarshal([]byte(jsonConfig), &s.cfg); err != nil {
+		return err
+	}
+	if s.cfg.ReconnectOnMsg {
+		return nil
+	}
+	return s.dial()
+}
+
+func (s *connSink) dial() error {
+	conn, err := net.Dial(s.cfg.Net, s.cfg.Addr)
+	if err != nil {
+		return err
+	}
+	s.conn = conn
+	return nil
+}
+
+func (s *connSink) WriteMsg(m LogMsg) error {
+	bytestring, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+	bytestring = append(bytestring, '\n')
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.cfg.ReconnectOnMsg {
+		if err := s.dial(); err != nil {
+			return err
+		}
+		defer func() {
+			s.conn.Close()
+			s.conn = nil
+		}()
+		_, err = s.conn.Write(bytestring)
+		return err
+	}
+	if s.conn == nil {
+		if err := s.dial(); err != nil {
+			return err
+		}
+	}
+	if _, err = s.conn.Write(bytestring); err != nil && s.cfg.Reconnect {
+		if derr := s.dial(); derr == nil {
+			_, err = s.conn.Write(bytestring)
+		}
+	}
+	return err
+}
+
+// WriteBatch encodes every message and sends them over the connection in
+// a single Write call, reusing the same reconnect/retry logic as WriteMsg
+// for the keep-alive case.
+func (s *connSink) WriteBatch(msgs []LogMsg) error {
+	var buf []byte
+	for _, m := range msgs {
+		bytestring, err := json.Marshal(m)
+		if err != nil {
+			return err
+		}
+		buf = append(buf, bytestring...)
+		buf = append(buf, '\n')
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.cfg.ReconnectOnMsg {
+		if err := s.dial(); err != nil {
+			return err
+		}
+		defer func() {
+			s.conn.Close()
+			s.conn = nil
+		}()
+		_, err := s.conn.Write(buf)
+		return err
+	}
+	if s.conn == nil {
+		if err := s.dial(); err != nil {
+			return err
+		}
+	}
+	_, err := s.conn.Write(buf)
+	if err != nil && s.cfg.Reconnect {
+		if derr := s.dial(); derr == nil {
+			_, err = s.conn.Write(buf)
+		}
+	}
+	return err
+}
+
+func (s *connSink) Flush() {}
+
+func (s *connSink) Destroy() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.conn != nil {
+		s.conn.Close()
+		s.conn = nil
+	}
+}
@@ -0,0 +1,48 @@
+package liblog
+
+import (
+	"bufio"
+	"net"
+	"testing"
+)
+
+func TestConnSinkWriteBatchSendsOneMessagePerLine(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer ln.Close()
+
+	received := make(chan int, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			received <- 0
+			return
+		}
+		defer conn.Close()
+		scanner := bufio.NewScanner(conn)
+		var lines int
+		for scanner.Scan() {
+			lines++
+		}
+		received <- lines
+	}()
+
+	s := &connSink{cfg: connSinkConfig{Net: "tcp", Addr: ln.Addr().String()}}
+	if err := s.Init(`{"net":"tcp","addr":"` + ln.Addr().String() + `"}`); err != nil {
+		t.Fatalf("Init: %v", err)
+	}
+	batch := []LogMsg{
+		{Message: "one", Level: InfoLevel},
+		{Message: "two", Level: InfoLevel},
+	}
+	if err := s.WriteBatch(batch); err != nil {
+		t.Fatalf("WriteBatch: %v", err)
+	}
+	s.Destroy()
+
+	if got := <-received; got != len(batch) {
+		t.Fatalf("server saw %d lines, want %d", got, len(batch))
+	}
+}
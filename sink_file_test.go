@@ -0,0 +1,56 @@
+package liblog
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFileSinkWriteBatchCoalescesWrites(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+
+	s := &fileSink{cfg: fileSinkConfig{Filename: path}}
+	if err := s.open(); err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	defer s.Destroy()
+
+	batch := []LogMsg{
+		{Message: "one", Level: InfoLevel},
+		{Message: "two", Level: InfoLevel},
+		{Message: "three", Level: InfoLevel},
+	}
+	if err := s.WriteBatch(batch); err != nil {
+		t.Fatalf("WriteBatch: %v", err)
+	}
+	s.Flush()
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("open log file: %v", err)
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	var lines int
+	for scanner.Scan() {
+		lines++
+	}
+	if lines != len(batch) {
+		t.Fatalf("got %d lines, want %d", lines, len(batch))
+	}
+}
+
+func TestFileSinkNeedsRotationDaily(t *testing.T) {
+	s := &fileSink{cfg: fileSinkConfig{Rotate: "daily"}, date: time.Now().Format(dateLayout)}
+	if s.needsRotation(0) {
+		t.Fatal("should not rotate: date unchanged")
+	}
+	s.date = fmt.Sprintf("2020-01-%02d", time.Now().Day())
+	if !s.needsRotation(0) {
+		t.Fatal("should rotate across a month boundary once the date differs")
+	}
+}
@@ -0,0 +1,103 @@
+package liblog
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/smtp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// smtpSinkConfig is the `smtp` key of a Logger.Configure JSON document.
+// Only ErrorLevel messages are collected, and they are mailed out as a
+// single batch every BatchIntervalSec (default 30s) rather than one
+// message per email.
+type smtpSinkConfig struct {
+	Host             string   `json:"host"`
+	Port             int      `json:"port"`
+	Username         string   `json:"username"`
+	Password         string   `json:"password"`
+	From             string   `json:"from"`
+	To               []string `json:"to"`
+	Subject          string   `json:"subject"`
+	BatchIntervalSec int      `json:"batchIntervalSec"`
+}
+
+// smtpSink batches ERROR-level messages and mails them out periodically.
+type smtpSink struct {
+	cfg   smtpSinkConfig
+	mu    sync.Mutex
+	batch []LogMsg
+	stop  chan struct{}
+}
+
+func (s *smtpSink) Init(jsonConfig string) error {
+	if err := json.Unmarshal([]byte(jsonConfig), &s.cfg); err != nil {
+		return err
+	}
+	if s.cfg.BatchIntervalSec <= 0 {
+		s.cfg.BatchIntervalSec = 30
+	}
+	if s.cfg.Subject == "" {
+		s.cfg.Subject = "log errors"
+	}
+	s.stop = make(chan struct{})
+	go s.loop()
+	return nil
+}
+
+func (s *smtpSink) loop() {
+	ticker := time.NewTicker(time.Duration(s.cfg.BatchIntervalSec) * time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			s.Flush()
+		case <-s.stop:
+			return
+		}
+	}
+}
+
+func (s *smtpSink) WriteMsg(m LogMsg) error {
+	if m.Level != ErrorLevel {
+		return nil
+	}
+	s.mu.Lock()
+	s.batch = append(s.batch, m)
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *smtpSink) Flush() {
+	s.mu.Lock()
+	batch := s.batch
+	s.batch = nil
+	s.mu.Unlock()
+	if len(batch) == 0 {
+		return
+	}
+	s.send(batch)
+}
+
+func (s *smtpSink) send(batch []LogMsg) error {
+	var body strings.Builder
+	for _, m := range batch {
+		bytestring, _ := json.Marshal(m)
+		body.Write(bytestring)
+		body.WriteByte('\n')
+	}
+	var auth smtp.Auth
+	if s.cfg.Username != "" {
+		auth = smtp.PlainAuth("", s.cfg.Username, s.cfg.Password, s.cfg.Host)
+	}
+	addr := fmt.Sprintf("%s:%d", s.cfg.Host, s.cfg.Port)
+	msg := fmt.Sprintf("Subject: %s\r\n\r\n%s", s.cfg.Subject, body.String())
+	return smtp.SendMail(addr, auth, s.cfg.From, s.cfg.To, []byte(msg))
+}
+
+func (s *smtpSink) Destroy() {
+	close(s.stop)
+	s.Flush()
+}
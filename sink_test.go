@@ -0,0 +1,93 @@
+package liblog
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+type closableBuffer struct {
+	bytes.Buffer
+	closed bool
+}
+
+func (c *closableBuffer) Close() error {
+	c.closed = true
+	return nil
+}
+
+func TestWriterSinkDestroyDoesNotCloseWriter(t *testing.T) {
+	buf := &closableBuffer{}
+	sink := &writerSink{w: buf}
+	sink.Destroy()
+	if buf.closed {
+		t.Fatal("writerSink.Destroy closed the wrapped writer; AddWriter callers never opt into that")
+	}
+}
+
+type trackingSink struct {
+	destroyed int32
+}
+
+func (s *trackingSink) Init(string) error     { return nil }
+func (s *trackingSink) WriteMsg(LogMsg) error { return nil }
+func (s *trackingSink) Flush()                {}
+func (s *trackingSink) Destroy()              { atomic.AddInt32(&s.destroyed, 1) }
+
+func TestConfigureDestroysReplacedSinks(t *testing.T) {
+	logger := Init("configure-destroy")
+	defer logger.StopSync()
+
+	old := &trackingSink{}
+	logger.sinkSet.replace([]LogSink{old})
+
+	RegisterSink("__test_noop", func() LogSink { return &trackingSink{} })
+	if err := logger.Configure(`{"__test_noop":{}}`); err != nil {
+		t.Fatalf("Configure: %v", err)
+	}
+	if atomic.LoadInt32(&old.destroyed) != 1 {
+		t.Fatalf("expected the replaced sink to be destroyed exactly once, got %d", old.destroyed)
+	}
+}
+
+func TestConfigureAndWriteDoNotRace(t *testing.T) {
+	os.Setenv("LOGLEVEL", "DEBUG")
+	defer os.Unsetenv("LOGLEVEL")
+	logger := Init("configure-race")
+	defer logger.StopSync()
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			logger.Info("message %d", i)
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 50; i++ {
+			_ = logger.Configure(`{"console":{}}`)
+			logger.SetEncoder(jsonEncoder{})
+		}
+	}()
+	wg.Wait()
+}
+
+func TestWriterSinkRoundTripsJSON(t *testing.T) {
+	buf := &bytes.Buffer{}
+	sink := &writerSink{w: buf, encoder: jsonEncoder{}}
+	if err := sink.WriteMsg(LogMsg{Message: "hello", Level: InfoLevel}); err != nil {
+		t.Fatalf("WriteMsg: %v", err)
+	}
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if decoded["message"] != "hello" {
+		t.Fatalf("got message %q, want %q", decoded["message"], "hello")
+	}
+}
@@ -0,0 +1,89 @@
+package liblog
+
+import (
+	"context"
+	"log/slog"
+	"runtime"
+)
+
+// Handler returns a slog.Handler that emits records through l's existing
+// JSON pipeline, preserving the timestamp/level/module/service_id schema
+// used by the rest of this package.
+func Handler(l *Logger) slog.Handler {
+	return &slogHandler{logger: l}
+}
+
+type slogHandler struct {
+	logger *Logger
+	group  string
+}
+
+// Enabled consults effectiveLevel, not just the logger's base Level, so a
+// vmodule override raising verbosity for this handler's module (e.g.
+// "auth=DEBUG") isn't silently ignored: slog never calls Handle once
+// Enabled has returned false.
+func (h *slogHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return slogLevel(level) >= h.logger.effectiveLevel(h.logger.module, "")
+}
+
+func (h *slogHandler) Handle(_ context.Context, r slog.Record) error {
+	fields := make(map[string]interface{}, r.NumAttrs())
+	r.Attrs(func(a slog.Attr) bool {
+		fields[h.prefixKey(a.Key)] = a.Value.Any()
+		return true
+	})
+	msg := LogMsg{
+		Timestamp: r.Time,
+		Level:     slogLevel(r.Level),
+		Message:   r.Message,
+		Module:    h.logger.module,
+		ModuleId:  h.logger.id,
+		Fields:    mergeFields(h.logger.fields, fields),
+	}
+	if r.PC != 0 {
+		frame, _ := runtime.CallersFrames([]uintptr{r.PC}).Next()
+		msg.SrcFile = srcFileForLog(frame.File)
+		msg.SrcLine = frame.Line
+	}
+	h.logger.enqueue(msg)
+	return nil
+}
+
+func (h *slogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	kv := make([]interface{}, 0, len(attrs)*2)
+	for _, a := range attrs {
+		kv = append(kv, h.prefixKey(a.Key), a.Value.Any())
+	}
+	return &slogHandler{logger: h.logger.With(kv...), group: h.group}
+}
+
+// WithGroup namespaces subsequently-added attribute keys under name,
+// dot-joined with any enclosing group, since LogMsg.Fields is a flat map
+// and same-named keys from different groups would otherwise collide.
+func (h *slogHandler) WithGroup(name string) slog.Handler {
+	group := name
+	if h.group != "" {
+		group = h.group + "." + name
+	}
+	return &slogHandler{logger: h.logger, group: group}
+}
+
+func (h *slogHandler) prefixKey(key string) string {
+	if h.group == "" {
+		return key
+	}
+	return h.group + "." + key
+}
+
+func slogLevel(level slog.Level) LogLevel {
+	switch {
+	case level < slog.LevelInfo:
+		return DebugLevel
+	case level < slog.LevelWarn:
+		return InfoLevel
+	case level < slog.LevelError:
+		return WarningLevel
+	default:
+		return ErrorLevel
+	}
+}
@@ -0,0 +1,35 @@
+package liblog
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+)
+
+func TestSlogHandlerEnabledHonorsVModule(t *testing.T) {
+	logger := Init("slog-vmodule")
+	defer logger.StopSync()
+	logger.SetLevel(WarningLevel)
+	if err := logger.SetVModule("slog-vmodule=DEBUG"); err != nil {
+		t.Fatalf("SetVModule: %v", err)
+	}
+
+	h := Handler(logger)
+	if !h.Enabled(context.Background(), slog.LevelDebug) {
+		t.Fatal("Enabled should honor the vmodule override raising this module to DEBUG")
+	}
+}
+
+func TestSlogHandlerWithGroupPrefixesKeys(t *testing.T) {
+	logger := Init("slog-group")
+	defer logger.StopSync()
+
+	h := Handler(logger).WithGroup("req").WithAttrs([]slog.Attr{slog.String("id", "abc")})
+	sh, ok := h.(*slogHandler)
+	if !ok {
+		t.Fatalf("got %T, want *slogHandler", h)
+	}
+	if _, ok := sh.logger.fields["req.id"]; !ok {
+		t.Fatalf("fields = %v, want a req.id key", sh.logger.fields)
+	}
+}
@@ -0,0 +1,196 @@
+package liblog
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+type vmodulePattern struct {
+	pattern string
+	level   LogLevel
+}
+
+type vmoduleList struct {
+	spec     string
+	patterns []vmodulePattern
+}
+
+// vmoduleState holds the compiled vmodule pattern list behind an
+// atomic.Pointer so lookups on the hot logging path are lock-free, plus a
+// cache of already-resolved (module, file) levels that SetVModule clears.
+type vmoduleState struct {
+	list  atomic.Pointer[vmoduleList]
+	cache sync.Map
+}
+
+// SetVModule installs a glog/geth-style per-module verbosity filter: a
+// comma-separated list of pattern=level clauses evaluated in order
+// against the log call's Module and SrcFile, e.g.
+// "auth=DEBUG,db/*=WARNING,*=INFO". The first matching pattern wins;
+// calls matching nothing fall back to the logger's base Level.
+func (logger *Logger) SetVModule(spec string) error {
+	list, err := parseVModule(spec)
+	if err != nil {
+		return err
+	}
+	logger.vmodule.list.Store(list)
+	logger.vmodule.invalidateCache()
+	return nil
+}
+
+// invalidateCache drops every cached (module, file) -> level resolution,
+// called whenever either the pattern list or the fallback base level
+// changes.
+func (s *vmoduleState) invalidateCache() {
+	s.cache.Range(func(key, _ interface{}) bool {
+		s.cache.Delete(key)
+		return true
+	})
+}
+
+func parseVModule(spec string) (*vmoduleList, error) {
+	list := &vmoduleList{spec: spec}
+	for _, clause := range strings.Split(spec, ",") {
+		clause = strings.TrimSpace(clause)
+		if clause == "" {
+			continue
+		}
+		kv := strings.SplitN(clause, "=", 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("liblog: invalid vmodule clause %q", clause)
+		}
+		level, err := parseLevelName(strings.TrimSpace(kv[1]))
+		if err != nil {
+			return nil, err
+		}
+		list.patterns = append(list.patterns, vmodulePattern{
+			pattern: strings.TrimSpace(kv[0]),
+			level:   level,
+		})
+	}
+	return list, nil
+}
+
+func parseLevelName(name string) (LogLevel, error) {
+	switch name {
+	case "DEBUG", "0":
+		return DebugLevel, nil
+	case "INFO", "1":
+		return InfoLevel, nil
+	case "WARNING", "2":
+		return WarningLevel, nil
+	case "ERROR", "3":
+		return ErrorLevel, nil
+	}
+	return 0, fmt.Errorf("liblog: unknown level %q", name)
+}
+
+// effectiveLevel resolves the level a message from (module, file) must
+// meet to be emitted: the first vmodule pattern match, or the logger's
+// base Level if none apply.
+func (logger *Logger) effectiveLevel(module, file string) LogLevel {
+	list := logger.vmodule.list.Load()
+	if list == nil || len(list.patterns) == 0 {
+		return logger.Level
+	}
+	cacheKey := module + "\x00" + file
+	if v, ok := logger.vmodule.cache.Load(cacheKey); ok {
+		return v.(LogLevel)
+	}
+	for _, p := range list.patterns {
+		if vmoduleMatch(p.pattern, module) || vmoduleMatch(p.pattern, file) {
+			logger.vmodule.cache.Store(cacheKey, p.level)
+			return p.level
+		}
+	}
+	logger.vmodule.cache.Store(cacheKey, logger.Level)
+	return logger.Level
+}
+
+func vmoduleMatch(pattern, value string) bool {
+	ok, err := path.Match(pattern, value)
+	return err == nil && ok
+}
+
+// srcFileForLog renders a caller's file path as "dir/file" instead of
+// just the bare basename, so a vmodule file pattern with a directory
+// component (e.g. "db/*=WARNING") can match against the immediate
+// parent directory rather than always falling through to the default.
+// Top-level files with no parent directory fall back to the basename.
+func srcFileForLog(fileName string) string {
+	fileName = filepath.ToSlash(fileName)
+	dir, file := filepath.Split(fileName)
+	dir = strings.TrimSuffix(dir, "/")
+	if dir == "" {
+		return file
+	}
+	return filepath.Base(dir) + "/" + file
+}
+
+type levelHandlerPayload struct {
+	Level   string `json:"level,omitempty"`
+	VModule string `json:"vmodule,omitempty"`
+}
+
+// LevelHandler returns an http.Handler exposing l's level and vmodule
+// spec for runtime control: GET reports the current values as JSON, PUT
+// accepts the same JSON shape to update either or both.
+func LevelHandler(l *Logger) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			list := l.vmodule.list.Load()
+			spec := ""
+			if list != nil {
+				spec = list.spec
+			}
+			writeLevelJSON(w, levelHandlerPayload{Level: levelName(l.Level), VModule: spec})
+		case http.MethodPut:
+			var payload levelHandlerPayload
+			if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			if payload.Level != "" {
+				level, err := parseLevelName(payload.Level)
+				if err != nil {
+					http.Error(w, err.Error(), http.StatusBadRequest)
+					return
+				}
+				l.SetLevel(level)
+			}
+			if payload.VModule != "" {
+				if err := l.SetVModule(payload.VModule); err != nil {
+					http.Error(w, err.Error(), http.StatusBadRequest)
+					return
+				}
+			}
+			writeLevelJSON(w, payload)
+		default:
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+}
+
+func levelName(level LogLevel) string {
+	bytestring, _ := level.MarshalJSON()
+	var name string
+	json.Unmarshal(bytestring, &name)
+	return name
+}
+
+func writeLevelJSON(w http.ResponseWriter, v levelHandlerPayload) {
+	w.Header().Set("Content-Type", "application/json")
+	bytestring, err := json.Marshal(v)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Write(bytestring)
+}
@@ -0,0 +1,45 @@
+package liblog
+
+import "testing"
+
+func TestSetLevelInvalidatesVModuleCache(t *testing.T) {
+	logger := Init("setlevel-cache")
+	defer logger.StopSync()
+
+	if err := logger.SetVModule("nomatch=DEBUG"); err != nil {
+		t.Fatalf("SetVModule: %v", err)
+	}
+	logger.SetLevel(WarningLevel)
+	if got := logger.effectiveLevel("other", ""); got != WarningLevel {
+		t.Fatalf("effectiveLevel = %v, want %v (caching the base level)", got, WarningLevel)
+	}
+
+	logger.SetLevel(ErrorLevel)
+	if got := logger.effectiveLevel("other", ""); got != ErrorLevel {
+		t.Fatalf("effectiveLevel after SetLevel = %v, want %v (cache must be invalidated)", got, ErrorLevel)
+	}
+}
+
+func TestVModuleDirectoryGlobMatchesSrcFileForLog(t *testing.T) {
+	logger := Init("dir-glob")
+	defer logger.StopSync()
+	logger.Level = InfoLevel
+
+	if err := logger.SetVModule("db/*=WARNING"); err != nil {
+		t.Fatalf("SetVModule: %v", err)
+	}
+
+	matched := srcFileForLog("/home/user/project/db/conn.go")
+	if got := logger.effectiveLevel("mod", matched); got != WarningLevel {
+		t.Fatalf("effectiveLevel(mod, %q) = %v, want %v (db/*=WARNING should match)", matched, got, WarningLevel)
+	}
+}
+
+func TestSrcFileForLogKeepsParentDirectory(t *testing.T) {
+	if got := srcFileForLog("/home/user/project/db/conn.go"); got != "db/conn.go" {
+		t.Fatalf("srcFileForLog = %q, want %q", got, "db/conn.go")
+	}
+	if got := srcFileForLog("main.go"); got != "main.go" {
+		t.Fatalf("srcFileForLog = %q, want %q for a file with no parent directory", got, "main.go")
+	}
+}